@@ -0,0 +1,380 @@
+package main
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"reflect"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+// Analyzer is the diwrappercheck analysis.Analyzer, runnable directly via its Run func (e.g.
+// from a custom multichecker) or through the diwrappercheck binary built from this package.
+var Analyzer = &analysis.Analyzer{
+	Name:     "diwrappercheck",
+	Doc:      "reports inject struct tags left unsatisfied by a diwrapper.InjectWrapper chain",
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+// terminalMethods are the InjectWrapper methods that finish a With* chain and actually wire the
+// graph together; a diagnostic is reported at the call site of whichever of these is used.
+var terminalMethods = map[string]bool{
+	"InitializeGraph":                    true,
+	"InitializeGraphWithImplicitObjects": true,
+	"InitializeGraphParallel":            true,
+	"InitializeGraphContext":             true,
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	insp := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+
+	insp.Preorder([]ast.Node{(*ast.FuncDecl)(nil), (*ast.FuncLit)(nil)}, func(n ast.Node) {
+		var body *ast.BlockStmt
+		switch f := n.(type) {
+		case *ast.FuncDecl:
+			body = f.Body
+		case *ast.FuncLit:
+			body = f.Body
+		}
+		if body != nil {
+			checkFunc(pass, body)
+		}
+	})
+
+	return nil, nil
+}
+
+// checkFunc looks for InitializeGraph*() terminal calls among body's own statements (not nested
+// function literals, which are checked separately as their own function) and verifies each one's
+// chain.
+func checkFunc(pass *analysis.Pass, body *ast.BlockStmt) {
+	calls := statementCalls(pass, body)
+
+	for _, sc := range calls {
+		sel, ok := sc.call.Fun.(*ast.SelectorExpr)
+		if !ok || !terminalMethods[sel.Sel.Name] {
+			continue
+		}
+		if !isInjectWrapperMethod(pass, sel) {
+			continue
+		}
+		checkChain(pass, sc.call, calls)
+	}
+}
+
+// stmtCall is one statement-level call found by statementCalls: either a bare call (`w.Foo()`) or
+// the right-hand side of a single-value assignment/definition (`w := Foo()`, `w = w.Foo()`), in
+// which case assignedTo is the object the result was assigned to.
+type stmtCall struct {
+	call       *ast.CallExpr
+	assignedTo types.Object
+}
+
+// statementCalls collects every call that's the whole of one statement within body, in source
+// order, including ones nested in if/for/switch bodies (but not in a nested function literal,
+// which is its own scope and checked separately). This is what lets checkChain see a With* call
+// made on a variable in an earlier statement, not just calls chained in one expression.
+func statementCalls(pass *analysis.Pass, body *ast.BlockStmt) []stmtCall {
+	var calls []stmtCall
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch s := n.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.ExprStmt:
+			if call, ok := s.X.(*ast.CallExpr); ok {
+				calls = append(calls, stmtCall{call: call})
+			}
+		case *ast.AssignStmt:
+			if len(s.Lhs) != 1 || len(s.Rhs) != 1 {
+				return true
+			}
+			call, ok := s.Rhs[0].(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+			var assignedTo types.Object
+			if ident, ok := s.Lhs[0].(*ast.Ident); ok {
+				assignedTo = pass.TypesInfo.ObjectOf(ident)
+			}
+			calls = append(calls, stmtCall{call: call, assignedTo: assignedTo})
+		}
+		return true
+	})
+	return calls
+}
+
+// isInjectWrapperMethod reports whether sel.X's static type is (or returns) *diwrapper.InjectWrapper.
+func isInjectWrapperMethod(pass *analysis.Pass, sel *ast.SelectorExpr) bool {
+	t := pass.TypesInfo.TypeOf(sel.X)
+	return isInjectWrapperType(t)
+}
+
+func isInjectWrapperType(t types.Type) bool {
+	if t == nil {
+		return false
+	}
+	ptr, ok := t.(*types.Pointer)
+	if !ok {
+		return false
+	}
+	named, ok := ptr.Elem().(*types.Named)
+	if !ok {
+		return false
+	}
+	obj := named.Obj()
+	return obj.Name() == "InjectWrapper" && obj.Pkg() != nil && strings.HasSuffix(obj.Pkg().Path(), "diwrapper")
+}
+
+// checkChain walks the chain rooted at the terminal InitializeGraph*() call backwards, collects
+// every value/constructor provided along the way, and reports any inject tag those values don't
+// satisfy. If the chain bottoms out on a plain variable (rather than a literal `diwrapper.New()`),
+// it also pulls in whatever every earlier statement in calls provided to (or via) that same
+// variable, so a With* call doesn't have to live in the same expression as the terminal call.
+func checkChain(pass *analysis.Pass, terminal *ast.CallExpr, calls []stmtCall) {
+	provided := newProvidedSet()
+
+	// reportAt is the terminal method name's own position (e.g. "InitializeGraph" in
+	// "foo.InitializeGraph()"), not the whole chain's start, so diagnostics land on the line
+	// that actually finishes the graph.
+	reportAt := terminal.Pos()
+	root := walkChain(pass, terminal, provided, &reportAt)
+
+	if rootObj := rootObject(pass, root); rootObj != nil {
+		for _, sc := range calls {
+			if sc.call == terminal || sc.call.Pos() >= terminal.Pos() {
+				continue
+			}
+			if sc.assignedTo != rootObj && rootObject(pass, chainRoot(sc.call)) != rootObj {
+				continue
+			}
+			walkChain(pass, sc.call, provided, nil)
+		}
+	}
+
+	for _, req := range provided.requirements() {
+		if !provided.satisfies(req) {
+			pass.Reportf(reportAt, "%s.%s (inject:%q) has no provider in this InitializeGraph chain",
+				req.owner, req.field, req.tag)
+		}
+	}
+}
+
+// walkChain walks call's fluent chain backwards (`a.B().C()` -> C, then B, then a), collecting
+// every value/constructor it provides into provided, and returns the expression the chain bottoms
+// out on: a plain variable, a literal `diwrapper.New()` call, or whatever else isn't itself a
+// call. If reportAt is non-nil, it's set to call's own method-name position, for diagnostics.
+func walkChain(pass *analysis.Pass, call *ast.CallExpr, provided *providedSet, reportAt *token.Pos) ast.Expr {
+	cur := call
+	first := true
+	for {
+		sel, ok := cur.Fun.(*ast.SelectorExpr)
+		if !ok {
+			return cur
+		}
+		if first && reportAt != nil {
+			*reportAt = sel.Sel.Pos()
+		}
+		first = false
+		collectProvided(pass, sel.Sel.Name, cur.Args, provided)
+
+		next, ok := sel.X.(*ast.CallExpr)
+		if !ok {
+			return sel.X
+		}
+		cur = next
+	}
+}
+
+// chainRoot is walkChain's traversal without collecting anything, used to find what variable (if
+// any) a statement's own chain is rooted on.
+func chainRoot(call *ast.CallExpr) ast.Expr {
+	cur := call
+	for {
+		sel, ok := cur.Fun.(*ast.SelectorExpr)
+		if !ok {
+			return cur
+		}
+		next, ok := sel.X.(*ast.CallExpr)
+		if !ok {
+			return sel.X
+		}
+		cur = next
+	}
+}
+
+// rootObject returns expr's object if it's a plain identifier referring to a variable (e.g. "w"
+// in "w.WithObject(...)"), and nil otherwise - in particular for a package name like "diwrapper"
+// in a literal "diwrapper.New()", so two unrelated inline chains don't get merged together.
+func rootObject(pass *analysis.Pass, expr ast.Expr) types.Object {
+	ident, ok := expr.(*ast.Ident)
+	if !ok {
+		return nil
+	}
+	v, ok := pass.TypesInfo.ObjectOf(ident).(*types.Var)
+	if !ok {
+		return nil
+	}
+	return v
+}
+
+// requirement is one `inject` tagged field found on a provided type.
+type requirement struct {
+	owner string // e.g. "*mypkg.Bbb"
+	field string
+	tag   string // the tag's value: "" for unnamed, or the dependency's name
+}
+
+type providedSet struct {
+	types []types.Type
+	names map[string]bool
+	reqs  []requirement
+	seen  map[string]bool
+}
+
+func newProvidedSet() *providedSet {
+	return &providedSet{names: map[string]bool{}, seen: map[string]bool{}}
+}
+
+func (p *providedSet) addType(t types.Type) {
+	if t == nil {
+		return
+	}
+	p.types = append(p.types, t)
+	p.collectRequirements(t)
+}
+
+func (p *providedSet) addName(name string) {
+	if name != "" {
+		p.names[name] = true
+	}
+}
+
+// collectRequirements records every inject-tagged field of the (struct, or pointer-to-struct)
+// type t, so checkChain can later verify each one has a satisfying provided value.
+func (p *providedSet) collectRequirements(t types.Type) {
+	st := underlyingStruct(t)
+	if st == nil {
+		return
+	}
+	key := t.String()
+	if p.seen[key] {
+		return
+	}
+	p.seen[key] = true
+
+	for f := 0; f < st.NumFields(); f++ {
+		tagVal, ok := reflect.StructTag(st.Tag(f)).Lookup("inject")
+		if !ok {
+			continue
+		}
+		p.reqs = append(p.reqs, requirement{
+			owner: t.String(),
+			field: st.Field(f).Name(),
+			tag:   tagVal,
+		})
+	}
+}
+
+func (p *providedSet) requirements() []requirement {
+	return p.reqs
+}
+
+func (p *providedSet) satisfies(req requirement) bool {
+	if req.tag != "" {
+		return p.names[req.tag]
+	}
+	return p.satisfiesUnnamed(req)
+}
+
+// satisfiesUnnamed reports whether some provided type is assignable to the field described by
+// req; it re-walks the owner type to find the field's declared type, since requirement only
+// keeps the owner's string form.
+func (p *providedSet) satisfiesUnnamed(req requirement) bool {
+	var fieldType types.Type
+	for _, t := range p.types {
+		if t.String() != req.owner {
+			continue
+		}
+		st := underlyingStruct(t)
+		if st == nil {
+			continue
+		}
+		for f := 0; f < st.NumFields(); f++ {
+			if st.Field(f).Name() == req.field {
+				fieldType = st.Field(f).Type()
+			}
+		}
+	}
+	if fieldType == nil {
+		return false
+	}
+	for _, cand := range p.types {
+		if cand.String() == req.owner {
+			continue
+		}
+		if types.AssignableTo(cand, fieldType) {
+			return true
+		}
+	}
+	return false
+}
+
+func underlyingStruct(t types.Type) *types.Struct {
+	if ptr, ok := t.(*types.Pointer); ok {
+		t = ptr.Elem()
+	}
+	st, _ := t.Underlying().(*types.Struct)
+	return st
+}
+
+// collectProvided inspects one With*/InitializeGraph* call in a chain and records whatever it
+// provides (a value's type, a name, or a provider function's return type) into provided.
+func collectProvided(pass *analysis.Pass, method string, args []ast.Expr, provided *providedSet) {
+	switch method {
+	case "WithObject", "WithObjectOrErr":
+		if len(args) > 0 {
+			provided.addType(pass.TypesInfo.TypeOf(args[0]))
+		}
+	case "WithObjects":
+		for _, a := range args {
+			provided.addType(pass.TypesInfo.TypeOf(a))
+		}
+	case "WithNamedObject":
+		if len(args) > 1 {
+			provided.addName(stringLiteral(args[0]))
+			provided.addType(pass.TypesInfo.TypeOf(args[1]))
+		}
+	case "WithProvider":
+		if len(args) > 0 {
+			provided.addType(providerReturnType(pass, args[0]))
+		}
+	case "WithNamedProvider":
+		if len(args) > 1 {
+			provided.addName(stringLiteral(args[0]))
+			provided.addType(providerReturnType(pass, args[1]))
+		}
+	}
+}
+
+// providerReturnType returns the first result type of a WithProvider/WithNamedProvider argument,
+// i.e. the type that constructor ultimately provides into the graph.
+func providerReturnType(pass *analysis.Pass, fn ast.Expr) types.Type {
+	sig, ok := pass.TypesInfo.TypeOf(fn).(*types.Signature)
+	if !ok || sig.Results().Len() == 0 {
+		return nil
+	}
+	return sig.Results().At(0).Type()
+}
+
+func stringLiteral(e ast.Expr) string {
+	lit, ok := e.(*ast.BasicLit)
+	if !ok {
+		return ""
+	}
+	return strings.Trim(lit.Value, `"`)
+}
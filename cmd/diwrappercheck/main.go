@@ -0,0 +1,14 @@
+// Command diwrappercheck is a go vet-style static analyzer for diwrapper. It resolves the
+// With*/InitializeGraph* call chains built on a *diwrapper.InjectWrapper within a single
+// function and reports, at the InitializeGraph*() call site, any `inject:""` / `inject:"name"`
+// struct tag that chain doesn't provide a value for. This is the same check InjectWrapper itself
+// performs at runtime (see the "%T not explicitly created" panic), just moved to build time.
+package main
+
+import (
+	"golang.org/x/tools/go/analysis/singlechecker"
+)
+
+func main() {
+	singlechecker.Main(Analyzer)
+}
@@ -0,0 +1,54 @@
+package a
+
+import (
+	"context"
+
+	"github.com/tkrajina/diwrapper"
+)
+
+type Config struct{}
+
+type Repo struct {
+	Config *Config `inject:""`
+}
+
+type NamedRepo struct {
+	Config *Config `inject:"primary"`
+}
+
+func ok() {
+	diwrapper.New().
+		WithObjects(new(Config), new(Repo)).
+		InitializeGraph()
+}
+
+func missing() {
+	diwrapper.New().
+		WithObject(new(Repo)).
+		InitializeGraph() // want `Repo.Config \(inject:""\) has no provider in this InitializeGraph chain`
+}
+
+func missingNamed() {
+	diwrapper.New().
+		WithObject(new(NamedRepo)).
+		InitializeGraph() // want `NamedRepo.Config \(inject:"primary"\) has no provider in this InitializeGraph chain`
+}
+
+func missingContext() {
+	diwrapper.New().
+		WithObject(new(Repo)).
+		InitializeGraphContext(context.Background()) // want `Repo.Config \(inject:""\) has no provider in this InitializeGraph chain`
+}
+
+func missingAcrossStatements() {
+	w := diwrapper.New()
+	w.WithObject(new(Repo))
+	w.InitializeGraph() // want `Repo.Config \(inject:""\) has no provider in this InitializeGraph chain`
+}
+
+func okAcrossStatements() {
+	w := diwrapper.New()
+	w.WithObject(new(Config))
+	w.WithObject(new(Repo))
+	w.InitializeGraph()
+}
@@ -0,0 +1,67 @@
+// Package diwrapper is a minimal stand-in for github.com/tkrajina/diwrapper, vendored only so
+// cmd/diwrappercheck's analysistest fixtures have a real package at the real import path to type
+// check against. It mirrors the public With*/InitializeGraph* API shape the analyzer inspects,
+// not its runtime behaviour.
+package diwrapper
+
+import "context"
+
+type InjectWrapper struct{}
+
+func New() *InjectWrapper {
+	return &InjectWrapper{}
+}
+
+func NewDebug() *InjectWrapper {
+	return &InjectWrapper{}
+}
+
+func (i *InjectWrapper) WithObject(object interface{}) *InjectWrapper {
+	return i
+}
+
+func (i *InjectWrapper) WithObjects(objects ...interface{}) *InjectWrapper {
+	return i
+}
+
+func (i *InjectWrapper) WithObjectOrErr(object interface{}, err error) *InjectWrapper {
+	return i
+}
+
+func (i *InjectWrapper) WithNamedObject(name string, object interface{}) *InjectWrapper {
+	return i
+}
+
+func (i *InjectWrapper) WithProvider(fn interface{}) *InjectWrapper {
+	return i
+}
+
+func (i *InjectWrapper) WithNamedProvider(name string, fn interface{}) *InjectWrapper {
+	return i
+}
+
+func (i *InjectWrapper) InitializeGraph() *InjectWrapper {
+	return i
+}
+
+func (i *InjectWrapper) InitializeGraphWithImplicitObjects() *InjectWrapper {
+	return i
+}
+
+func (i *InjectWrapper) InitializeGraphParallel() *InjectWrapper {
+	return i
+}
+
+func (i *InjectWrapper) InitializeGraphContext(ctx context.Context) error {
+	return nil
+}
+
+func (i *InjectWrapper) Stop() {}
+
+func (i *InjectWrapper) StopContext(ctx context.Context) error {
+	return nil
+}
+
+func (i *InjectWrapper) Scope() *InjectWrapper {
+	return &InjectWrapper{}
+}
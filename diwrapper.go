@@ -1,10 +1,12 @@
 package diwrapper
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"reflect"
 	"sync"
+	"time"
 
 	"github.com/facebookgo/inject"
 )
@@ -23,22 +25,76 @@ type InjectWrapper struct {
 	// this slice is here because we want to initialize objects in the order as they are added (after the graph is generated):
 	objects    [][]*inject.Object
 	tmpObjects []*inject.Object
+
+	// maxInitConcurrency bounds how many objects from the same dependency level
+	// InitializeGraphParallel initializes at once. Zero means unbounded.
+	maxInitConcurrency int
+	// depOrder is the topological order computed by InitializeGraphParallel, kept around
+	// so Stop() can clean objects in the reverse order of that same graph.
+	depOrder []*inject.Object
+
+	// initTimeout and cleanTimeout bound how long InitializeGraphContext/StopContext wait on a
+	// single object's InitCtx/CleanCtx (or Init/Clean, which don't accept a context but are
+	// still raced against it). Zero means no per-object timeout, only the caller's context.
+	initTimeout  time.Duration
+	cleanTimeout time.Duration
+
+	// ctxInitialized tracks, in completion order, every object InitializeGraphContext has
+	// already initialized, so it can roll them back in reverse if a later object fails or the
+	// context is cancelled mid-init.
+	ctxInitializedMu sync.Mutex
+	ctxInitialized   []*inject.Object
+
+	// providerSpecs maps a provider's placeholder object to the providerSpec that fills it in,
+	// so resolveProvider can find and call it at the right point in the normal init order.
+	providerSpecs map[*inject.Object]*providerSpec
+
+	// parent is set on wrappers returned by Scope(); it's where resolution falls back to once
+	// the child's own objects don't have a match.
+	parent *InjectWrapper
+	// childrenMu guards children, since scopes/requests may be created and stopped concurrently
+	// against a shared root.
+	childrenMu sync.Mutex
+	// children holds every live wrapper created from this one via Scope(), so the root's Stop()
+	// can cascade into still-live children. StopContext splices a child back out of its parent's
+	// children once it's been stopped, so repeated Scope()/Stop() cycles don't leak.
+	children []*InjectWrapper
+	// inherited holds the objects a child got from its parent via Scope(). They're provided
+	// into g so the child's own objects can be wired to them, and they count towards
+	// checkNoImplicitObjects, but they're never part of objects/tmpObjects so the child never
+	// initializes or cleans them itself.
+	inherited []*inject.Object
+}
+
+// Option configures an InjectWrapper at construction time, see New().
+type Option func(*InjectWrapper)
+
+// WithMaxInitConcurrency limits how many objects from a single dependency level
+// InitializeGraphParallel will initialize concurrently. The default, 0, means unbounded.
+func WithMaxInitConcurrency(n int) Option {
+	return func(i *InjectWrapper) {
+		i.maxInitConcurrency = n
+	}
 }
 
 // NewDebug starts a diwrapper with debug output
-func NewDebug() *InjectWrapper {
-	di := New()
+func NewDebug(opts ...Option) *InjectWrapper {
+	di := New(opts...)
 	di.g.Logger = &log{}
 	return di
 }
 
-func New() *InjectWrapper {
+func New(opts ...Option) *InjectWrapper {
 	var g inject.Graph
-	return &InjectWrapper{
+	i := &InjectWrapper{
 		g:          &g,
 		objects:    [][]*inject.Object{},
 		tmpObjects: []*inject.Object{},
 	}
+	for _, opt := range opts {
+		opt(i)
+	}
+	return i
 }
 
 func (i *InjectWrapper) log(format string, v ...interface{}) {
@@ -124,7 +180,7 @@ func (i *InjectWrapper) AllObjects() []interface{} {
 // MustFindObject privides an object of the specified type and name (name can be empty for unnamed objects). Note that
 // this function is only for debugging and testing. In production, objects should be used injected and never retrieved
 // with this. That's why this method panics!
-func (i InjectWrapper) MustGetNamedObject(sample interface{}, name string) interface{} {
+func (i *InjectWrapper) MustGetNamedObject(sample interface{}, name string) interface{} {
 	sampleType := reflect.TypeOf(sample)
 	if sampleType.Kind() != reflect.Ptr {
 		panic(fmt.Sprintf("Sample must be interface, found %T", sample))
@@ -136,15 +192,27 @@ func (i InjectWrapper) MustGetNamedObject(sample interface{}, name string) inter
 			}
 		}
 	}
+	if i.parent != nil {
+		return i.parent.MustGetNamedObject(sample, name)
+	}
 	panic(fmt.Sprintf("Object not found: %s.%T", name, sample))
 }
 
 // MustGetObject: see MustGetNamedObject
-func (i InjectWrapper) MustGetObject(sample interface{}) interface{} {
+func (i *InjectWrapper) MustGetObject(sample interface{}) interface{} {
 	return i.MustGetNamedObject(sample, "")
 }
 
 func (i *InjectWrapper) CheckNoImplicitObjects() *InjectWrapper {
+	if err := i.checkNoImplicitObjects(); err != nil {
+		panic(err.Error())
+	}
+	return i
+}
+
+// checkNoImplicitObjects is the error-returning counterpart of CheckNoImplicitObjects, used by
+// the context-aware InitializeGraphContext so it can report failures instead of panicking.
+func (i *InjectWrapper) checkNoImplicitObjects() error {
 	for _, o := range i.g.Objects() {
 		var oOK bool
 		for _, objs := range i.objects {
@@ -154,14 +222,19 @@ func (i *InjectWrapper) CheckNoImplicitObjects() *InjectWrapper {
 				}
 			}
 		}
+		for _, obj := range i.inherited {
+			if obj.Value == o.Value {
+				oOK = true
+			}
+		}
 		if oOK {
 			i.log("%T OK\n", o.Value)
 		} else {
-			panic(fmt.Sprintf("%T not explicitly created", o.Value))
+			return fmt.Errorf("%T not explicitly created", o.Value)
 		}
 	}
 
-	return i
+	return nil
 }
 
 // InitializeGraphWithImplicitObjects initializes a graph allowing implicitly created objects. Those are objects not specified with one of the With...() methods.
@@ -192,9 +265,13 @@ func (i *InjectWrapper) initAsync(objs []*inject.Object) {
 
 	for _, obj := range objs {
 		wg.Add(1)
-		go func() {
+		go func(obj *inject.Object) {
 			defer wg.Done()
 
+			if err := i.resolveProvider(obj); err != nil {
+				panic(err.Error())
+			}
+
 			if initializer, is := obj.Value.(Initializer); is {
 				i.log("Initializing %T", obj.Value)
 				defer i.log("Initialized %T", obj.Value)
@@ -202,28 +279,44 @@ func (i *InjectWrapper) initAsync(objs []*inject.Object) {
 					panic(fmt.Sprintf("Error initializing privided object %T:%s", obj, err.Error()))
 				}
 			}
-		}()
+		}(obj)
 	}
 	wg.Wait()
 }
 
-// InitializeGraph initializes a graph, but fails if an object is not specified with one of the With() methods.
+// InitializeGraph initializes a graph, but fails if an object is not specified with one of the
+// With() methods. It's a thin, panicking wrapper around InitializeGraphContext, kept for
+// backwards compatibility with code that doesn't care about contexts or structured errors.
 func (i *InjectWrapper) InitializeGraph() *InjectWrapper {
-	_ = i.InitializeGraphWithImplicitObjects()
-	return i.CheckNoImplicitObjects()
+	if err := i.InitializeGraphContext(context.Background()); err != nil {
+		panic(err.Error())
+	}
+	return i
 }
 
+// Stop is a thin wrapper around StopContext, kept for backwards compatibility. Errors are
+// printed to stderr rather than returned, same as before.
 func (i *InjectWrapper) Stop() {
-	for _, obj := range i.AllObjects() {
-		if cleaner, is := obj.(Cleaner); is {
-			i.log("Cleaning %T", obj)
-			if err := cleaner.Clean(); err != nil {
-				fmt.Fprintf(os.Stderr, "Error cleaning %T: %+v\n", obj, err)
-			}
-		}
+	if err := i.StopContext(context.Background()); err != nil {
+		fmt.Fprintf(os.Stderr, "Error cleaning: %+v\n", err)
 	}
 }
 
+// stopOrder returns the objects to clean, in the order Stop() should clean them in. If
+// InitializeGraphParallel computed a dependency graph, objects are cleaned in reverse
+// topological order, so e.g. a DB pool is only shut down after its consumers. Otherwise objects
+// are cleaned in the order they were added, which was the previous behaviour.
+func (i *InjectWrapper) stopOrder() []interface{} {
+	if i.depOrder == nil {
+		return i.AllObjects()
+	}
+	res := make([]interface{}, len(i.depOrder))
+	for idx, o := range i.depOrder {
+		res[len(i.depOrder)-1-idx] = o.Value
+	}
+	return res
+}
+
 func (i *InjectWrapper) Stopper() func() {
 	return func() {
 		i.Stop()
@@ -0,0 +1,239 @@
+package diwrapper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/facebookgo/inject"
+)
+
+// ContextInitializer is preferred over Initializer by InitializeGraphContext when an object
+// implements both, so a hung initializer can be cancelled instead of blocking startup forever.
+type ContextInitializer interface {
+	InitCtx(ctx context.Context) error
+}
+
+// ContextCleaner is preferred over Cleaner by StopContext when an object implements both.
+type ContextCleaner interface {
+	CleanCtx(ctx context.Context) error
+}
+
+// WithInitTimeout bounds how long InitializeGraphContext waits on a single object's
+// InitCtx/Init. The default, 0, means no per-object timeout beyond the caller's context.
+func WithInitTimeout(d time.Duration) Option {
+	return func(i *InjectWrapper) {
+		i.initTimeout = d
+	}
+}
+
+// WithCleanTimeout bounds how long StopContext waits on a single object's CleanCtx/Clean. The
+// default, 0, means no per-object timeout beyond the caller's context.
+func WithCleanTimeout(d time.Duration) Option {
+	return func(i *InjectWrapper) {
+		i.cleanTimeout = d
+	}
+}
+
+// InitializeGraphContext is the context-aware, error-returning counterpart of InitializeGraph.
+// It initializes each InitSync()/InitAsync() batch in turn, preferring ContextInitializer over
+// Initializer, and bounding each object by WithInitTimeout. Errors from every object in a batch
+// are combined with errors.Join rather than panicking. If a batch fails, or ctx is cancelled
+// before the next batch starts, every object already initialized is rolled back (its
+// Clean/CleanCtx is called) in reverse order before the error is returned.
+func (i *InjectWrapper) InitializeGraphContext(ctx context.Context) error {
+	i.InitSync()
+	i.log("Initializing %d objects (context)", len(i.objects))
+
+	if err := i.g.Populate(); err != nil {
+		return fmt.Errorf("error populating graph: %w", err)
+	}
+
+	for _, objs := range i.objects {
+		if err := i.initBatchCtx(ctx, objs); err != nil {
+			return i.rollbackAfter(ctx, err)
+		}
+		if err := ctx.Err(); err != nil {
+			return i.rollbackAfter(ctx, err)
+		}
+	}
+
+	if err := i.checkNoImplicitObjects(); err != nil {
+		return i.rollbackAfter(ctx, err)
+	}
+	return nil
+}
+
+func (i *InjectWrapper) rollbackAfter(ctx context.Context, cause error) error {
+	if err := i.rollback(ctx); err != nil {
+		return errors.Join(cause, err)
+	}
+	return cause
+}
+
+// initBatchCtx initializes every object in a batch concurrently and joins their errors.
+func (i *InjectWrapper) initBatchCtx(ctx context.Context, objs []*inject.Object) error {
+	type result struct {
+		obj *inject.Object
+		err error
+	}
+	results := make(chan result, len(objs))
+
+	for _, obj := range objs {
+		obj := obj
+		go func() {
+			results <- result{obj: obj, err: i.initOneCtx(ctx, obj)}
+		}()
+	}
+
+	var errs []error
+	for range objs {
+		r := <-results
+		if r.err != nil {
+			errs = append(errs, fmt.Errorf("%T: %w", r.obj.Value, r.err))
+			continue
+		}
+		i.markInitialized(r.obj)
+	}
+
+	return errors.Join(errs...)
+}
+
+func (i *InjectWrapper) markInitialized(obj *inject.Object) {
+	i.ctxInitializedMu.Lock()
+	defer i.ctxInitializedMu.Unlock()
+	i.ctxInitialized = append(i.ctxInitialized, obj)
+}
+
+func (i *InjectWrapper) initOneCtx(ctx context.Context, obj *inject.Object) error {
+	if err := i.resolveProvider(obj); err != nil {
+		return err
+	}
+
+	octx, cancel := i.withTimeout(ctx, i.initTimeout)
+	defer cancel()
+
+	if initializer, is := obj.Value.(ContextInitializer); is {
+		i.log("Initializing %T (ctx)", obj.Value)
+		defer i.log("Initialized %T", obj.Value)
+		return initializer.InitCtx(octx)
+	}
+
+	initializer, is := obj.Value.(Initializer)
+	if !is {
+		return nil
+	}
+
+	i.log("Initializing %T", obj.Value)
+	defer i.log("Initialized %T", obj.Value)
+	done := make(chan error, 1)
+	go func() { done <- initializer.Init() }()
+	select {
+	case err := <-done:
+		return err
+	case <-octx.Done():
+		return octx.Err()
+	}
+}
+
+// rollback cleans up everything InitializeGraphContext has initialized so far, in reverse
+// order, and clears ctxInitialized. Cleanup always runs against a background context bounded
+// only by WithCleanTimeout, since the context the failed init was cancelled with shouldn't also
+// prevent cleaning up after it.
+func (i *InjectWrapper) rollback(ctx context.Context) error {
+	i.ctxInitializedMu.Lock()
+	toClean := i.ctxInitialized
+	i.ctxInitialized = nil
+	i.ctxInitializedMu.Unlock()
+
+	if len(toClean) == 0 {
+		return nil
+	}
+	i.log("Rolling back %d initialized objects", len(toClean))
+
+	var errs []error
+	for idx := len(toClean) - 1; idx >= 0; idx-- {
+		obj := toClean[idx]
+		if err := i.cleanValueCtx(context.Background(), obj.Value); err != nil {
+			errs = append(errs, fmt.Errorf("%T: %w", obj.Value, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// StopContext is the context-aware, error-returning counterpart of Stop. It cleans objects in
+// stopOrder() (reverse topological order if InitializeGraphParallel was used, the order they
+// were added otherwise), preferring ContextCleaner over Cleaner, and joins every error instead
+// of only printing it. It then cascades into every still-live child created via Scope(), so
+// stopping the root also stops any request-scoped wrappers layered on top of it. Once a child is
+// done stopping, it splices itself out of its parent's children, so it's not kept alive (and
+// cleaned again) for the rest of the root's lifetime.
+func (i *InjectWrapper) StopContext(ctx context.Context) error {
+	var errs []error
+	for _, obj := range i.stopOrder() {
+		if err := i.cleanValueCtx(ctx, obj); err != nil {
+			errs = append(errs, fmt.Errorf("%T: %w", obj, err))
+		}
+	}
+
+	i.childrenMu.Lock()
+	children := append([]*InjectWrapper{}, i.children...)
+	i.childrenMu.Unlock()
+
+	for _, child := range children {
+		if err := child.StopContext(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if i.parent != nil {
+		i.parent.removeChild(i)
+	}
+
+	return errors.Join(errs...)
+}
+
+// removeChild splices child out of i's children once it's been stopped.
+func (i *InjectWrapper) removeChild(child *InjectWrapper) {
+	i.childrenMu.Lock()
+	defer i.childrenMu.Unlock()
+	for idx, c := range i.children {
+		if c == child {
+			i.children = append(i.children[:idx], i.children[idx+1:]...)
+			return
+		}
+	}
+}
+
+func (i *InjectWrapper) cleanValueCtx(ctx context.Context, value interface{}) error {
+	octx, cancel := i.withTimeout(ctx, i.cleanTimeout)
+	defer cancel()
+
+	if cleaner, is := value.(ContextCleaner); is {
+		i.log("Cleaning %T (ctx)", value)
+		return cleaner.CleanCtx(octx)
+	}
+
+	cleaner, is := value.(Cleaner)
+	if !is {
+		return nil
+	}
+
+	i.log("Cleaning %T", value)
+	done := make(chan error, 1)
+	go func() { done <- cleaner.Clean() }()
+	select {
+	case err := <-done:
+		return err
+	case <-octx.Done():
+		return octx.Err()
+	}
+}
+
+func (i *InjectWrapper) withTimeout(ctx context.Context, d time.Duration) (context.Context, context.CancelFunc) {
+	if d <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, d)
+}
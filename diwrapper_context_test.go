@@ -0,0 +1,84 @@
+package diwrapper
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type ctxInitStruct struct {
+	initialized bool
+	cleaned     bool
+}
+
+func (s *ctxInitStruct) InitCtx(ctx context.Context) error {
+	s.initialized = true
+	return nil
+}
+
+func (s *ctxInitStruct) CleanCtx(ctx context.Context) error {
+	s.cleaned = true
+	return nil
+}
+
+var (
+	_ ContextInitializer = (*ctxInitStruct)(nil)
+	_ ContextCleaner     = (*ctxInitStruct)(nil)
+)
+
+func TestInitializeGraphContextSuccess(t *testing.T) {
+	s := &ctxInitStruct{}
+
+	di := New().WithObject(s)
+	err := di.InitializeGraphContext(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, s.initialized)
+
+	assert.NoError(t, di.StopContext(context.Background()))
+	assert.True(t, s.cleaned)
+}
+
+type failingCtxInit struct{}
+
+func (s *failingCtxInit) InitCtx(ctx context.Context) error {
+	return errors.New("boom")
+}
+
+func TestInitializeGraphContextReturnsErrorInsteadOfPanicking(t *testing.T) {
+	di := New().WithObject(&failingCtxInit{})
+	err := di.InitializeGraphContext(context.Background())
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "boom")
+}
+
+func TestInitializeGraphContextRollsBackOnLaterFailure(t *testing.T) {
+	ok := &ctxInitStruct{}
+
+	di := New().
+		WithObjects(ok).
+		InitAsync().
+		WithObject(&failingCtxInit{}).
+		InitAsync()
+
+	err := di.InitializeGraphContext(context.Background())
+	assert.Error(t, err)
+	assert.True(t, ok.initialized)
+	assert.True(t, ok.cleaned, "objects initialized before the failure must be rolled back")
+}
+
+type slowCtxInit struct{}
+
+func (s *slowCtxInit) InitCtx(ctx context.Context) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestInitializeGraphContextHonoursInitTimeout(t *testing.T) {
+	di := New(WithInitTimeout(10 * time.Millisecond)).WithObject(&slowCtxInit{})
+	err := di.InitializeGraphContext(context.Background())
+	assert.Error(t, err)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
@@ -0,0 +1,224 @@
+package diwrapper
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/facebookgo/inject"
+)
+
+// InitializeGraphParallel initializes a graph without relying on the caller to batch objects
+// with InitAsync()/InitSync(): it derives the initialization order itself. It walks every
+// registered object's `inject:""` / `inject:"name"` struct tags to build a DAG of
+// object->dependency edges, groups objects into "levels" (a level is every object whose
+// dependencies are already initialized) and initializes each level concurrently, bounded by
+// WithMaxInitConcurrency. Stop() reuses the same graph and cleans objects in reverse
+// topological order, so e.g. a DB pool is only shut down after its consumers.
+//
+// Objects with no Initializer still participate in the graph, so their dependents wait for
+// them. A dependency cycle panics with the full cycle path.
+func (i *InjectWrapper) InitializeGraphParallel() *InjectWrapper {
+	i.InitSync()
+	i.log("Initializing %d objects (graph-parallel)", len(i.objects))
+
+	if err := i.g.Populate(); err != nil {
+		panic(fmt.Sprintf("Error populating graph: %s", err))
+	}
+
+	levels := i.dependencyLevels()
+	for _, level := range levels {
+		i.initParallel(level)
+	}
+
+	return i.CheckNoImplicitObjects()
+}
+
+// allRegisteredObjects flattens every object added so far, across all InitSync()/InitAsync()
+// batches, in the order they were added.
+func (i *InjectWrapper) allRegisteredObjects() []*inject.Object {
+	all := make([]*inject.Object, 0, len(i.objects))
+	for _, objs := range i.objects {
+		all = append(all, objs...)
+	}
+	return all
+}
+
+// dependencyLevels groups every registered object into levels using a Kahn-style topological
+// sort: level 0 holds every object with no unresolved dependencies, level 1 holds objects whose
+// dependencies are all in level 0, and so on. The resulting flattened order is also kept in
+// i.depOrder, so Stop() can clean up in reverse.
+func (i *InjectWrapper) dependencyLevels() [][]*inject.Object {
+	all := i.allRegisteredObjects()
+	deps := make(map[*inject.Object][]*inject.Object, len(all))
+	for _, o := range all {
+		deps[o] = i.dependenciesOf(o, all)
+	}
+
+	done := make(map[*inject.Object]bool, len(all))
+	var levels [][]*inject.Object
+	var order []*inject.Object
+
+	for len(done) < len(all) {
+		var level []*inject.Object
+		for _, o := range all {
+			if done[o] {
+				continue
+			}
+			if allDone(deps[o], done) {
+				level = append(level, o)
+			}
+		}
+		if len(level) == 0 {
+			panic(fmt.Sprintf("Cycle detected in inject graph: %s", dependencyCycle(all, deps)))
+		}
+		for _, o := range level {
+			done[o] = true
+		}
+		levels = append(levels, level)
+		order = append(order, level...)
+	}
+
+	i.depOrder = order
+	return levels
+}
+
+func allDone(deps []*inject.Object, done map[*inject.Object]bool) bool {
+	for _, d := range deps {
+		if !done[d] {
+			return false
+		}
+	}
+	return true
+}
+
+// dependenciesOf reflects over obj's `inject` struct tags and resolves each one against the
+// other registered objects, the same way facebookgo/inject itself resolves fields during
+// Populate(): unnamed tags match by assignable type, named tags match by name. If obj is
+// provider-backed, its provider function's own parameters are added as dependencies too, so the
+// provider isn't called until whatever it depends on has already been initialized.
+func (i *InjectWrapper) dependenciesOf(obj *inject.Object, all []*inject.Object) []*inject.Object {
+	var deps []*inject.Object
+	if p, ok := i.providerSpecs[obj]; ok {
+		deps = append(deps, providerArgDependencies(p, all)...)
+	}
+
+	v := reflect.ValueOf(obj.Value)
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return deps
+	}
+
+	t := v.Type()
+	for f := 0; f < t.NumField(); f++ {
+		field := t.Field(f)
+		name, ok := field.Tag.Lookup("inject")
+		if !ok {
+			continue
+		}
+		for _, cand := range all {
+			if cand == obj {
+				continue
+			}
+			if name != "" {
+				if cand.Name == name {
+					deps = append(deps, cand)
+					break
+				}
+				continue
+			}
+			if cand.Name == "" && reflect.TypeOf(cand.Value).AssignableTo(field.Type) {
+				deps = append(deps, cand)
+				break
+			}
+		}
+	}
+	return deps
+}
+
+// dependencyCycle finds and describes one cycle among the objects still unresolved in deps, for
+// use in the panic message once dependencyLevels can't make progress.
+func dependencyCycle(all []*inject.Object, deps map[*inject.Object][]*inject.Object) string {
+	const white, gray, black = 0, 1, 2
+	color := make(map[*inject.Object]int, len(all))
+	var stack []*inject.Object
+
+	var visit func(o *inject.Object) []*inject.Object
+	visit = func(o *inject.Object) []*inject.Object {
+		color[o] = gray
+		stack = append(stack, o)
+		for _, d := range deps[o] {
+			switch color[d] {
+			case gray:
+				idx := 0
+				for j, s := range stack {
+					if s == d {
+						idx = j
+						break
+					}
+				}
+				cycle := append([]*inject.Object{}, stack[idx:]...)
+				return append(cycle, d)
+			case white:
+				if cycle := visit(d); cycle != nil {
+					return cycle
+				}
+			}
+		}
+		stack = stack[:len(stack)-1]
+		color[o] = black
+		return nil
+	}
+
+	for _, o := range all {
+		if color[o] == white {
+			if cycle := visit(o); cycle != nil {
+				names := make([]string, len(cycle))
+				for idx, o := range cycle {
+					names[idx] = fmt.Sprintf("%T", o.Value)
+				}
+				return strings.Join(names, " -> ")
+			}
+		}
+	}
+	return "unknown cycle"
+}
+
+// initParallel initializes every object in a level concurrently, bounded by
+// i.maxInitConcurrency (0 means unbounded).
+func (i *InjectWrapper) initParallel(objs []*inject.Object) {
+	limit := i.maxInitConcurrency
+	if limit <= 0 || limit > len(objs) {
+		limit = len(objs)
+	}
+	sem := make(chan struct{}, limit)
+	wg := &sync.WaitGroup{}
+
+	for _, obj := range objs {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(obj *inject.Object) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			if err := i.resolveProvider(obj); err != nil {
+				panic(err.Error())
+			}
+
+			initializer, is := obj.Value.(Initializer)
+			if !is {
+				return
+			}
+			i.log("Initializing %T", obj.Value)
+			defer i.log("Initialized %T", obj.Value)
+			if err := initializer.Init(); err != nil {
+				panic(fmt.Sprintf("Error initializing privided object %T:%s", obj.Value, err.Error()))
+			}
+		}(obj)
+	}
+
+	wg.Wait()
+}
@@ -0,0 +1,89 @@
+package diwrapper
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type graphDB struct {
+	initialized bool
+	stopped     bool
+}
+
+func (d *graphDB) Init() error {
+	time.Sleep(10 * time.Millisecond)
+	d.initialized = true
+	return nil
+}
+
+func (d *graphDB) Clean() error {
+	d.stopped = true
+	return nil
+}
+
+type graphRepo struct {
+	DB      *graphDB `inject:""`
+	started int32
+}
+
+func (r *graphRepo) Init() error {
+	if !r.DB.initialized {
+		panic("repo initialized before its DB dependency")
+	}
+	atomic.AddInt32(&r.started, 1)
+	return nil
+}
+
+func TestInitializeGraphParallelOrdersByDependency(t *testing.T) {
+	db := &graphDB{}
+	repo := &graphRepo{}
+
+	di := New().
+		WithObjects(db, repo).
+		InitializeGraphParallel()
+
+	assert.True(t, db.initialized)
+	assert.Equal(t, int32(1), repo.started)
+
+	di.Stop()
+	assert.True(t, db.stopped)
+}
+
+type Aaa2 struct {
+	Bbb2 *Bbb2Ref `inject:""`
+}
+
+type Bbb2Ref struct {
+	Aaa2 *Aaa2 `inject:""`
+}
+
+func TestInitializeGraphParallelDetectsCycle(t *testing.T) {
+	defer func() {
+		r := recover()
+		assert.NotNil(t, r, "expected a panic on cyclic dependencies")
+	}()
+
+	a := &Aaa2{}
+	b := &Bbb2Ref{Aaa2: a}
+	a.Bbb2 = b
+
+	New().
+		WithObjects(a, b).
+		InitializeGraphParallel()
+
+	t.FailNow()
+}
+
+func TestWithMaxInitConcurrency(t *testing.T) {
+	db := &graphDB{}
+
+	di := NewDebug(WithMaxInitConcurrency(1)).
+		WithObject(db).
+		InitializeGraphParallel()
+
+	assert.True(t, db.initialized)
+	di.Stop()
+}
@@ -0,0 +1,216 @@
+package diwrapper
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/facebookgo/inject"
+)
+
+// providerSpec is a registered constructor, pre-wired into the graph via a placeholder so
+// facebookgo/inject can resolve other objects' inject tags against it, but not actually called
+// until resolveProvider runs it as part of the normal init order.
+type providerSpec struct {
+	name        string
+	fn          reflect.Value
+	fnType      reflect.Type
+	placeholder reflect.Value // the *T provided into the graph; filled in by resolveProvider
+	resolved    bool
+}
+
+// WithProvider registers a constructor to be invoked once its parameters can be resolved from
+// the graph and have themselves finished initializing, instead of requiring the caller to build
+// the value imperatively before WithObject. fn must be a function of the form `func(deps...) T`
+// or `func(deps...) (T, error)`, where T is a pointer type: a placeholder *T is provided into the
+// graph immediately so other objects' inject tags can be wired to it, but fn itself isn't called
+// until the object's turn in the usual init order. InitializeGraphParallel treats fn's parameters
+// as dependency edges, so they (e.g. a DB driver whose Init() opens the connection) are
+// initialized before fn is called. InitializeGraph and the other batch-ordered methods initialize
+// objects in the order they were added, so there fn's dependencies are initialized first only if
+// they were added in an earlier batch; otherwise fn may run before their Init(). Each parameter is
+// resolved by type, except for a parameter whose type is a struct with `name:"..."` tags on its
+// fields, which is instead built field by field from named objects (see WithNamedObject).
+func (i *InjectWrapper) WithProvider(fn interface{}) *InjectWrapper {
+	return i.withProvider("", fn)
+}
+
+// WithNamedProvider is like WithProvider, but the constructed value is registered under name.
+func (i *InjectWrapper) WithNamedProvider(name string, fn interface{}) *InjectWrapper {
+	return i.withProvider(name, fn)
+}
+
+func (i *InjectWrapper) withProvider(name string, fn interface{}) *InjectWrapper {
+	v := reflect.ValueOf(fn)
+	t := v.Type()
+	if t.Kind() != reflect.Func {
+		panic(fmt.Sprintf("WithProvider expects a function, found %T", fn))
+	}
+	if t.NumOut() != 1 && t.NumOut() != 2 {
+		panic(fmt.Sprintf("WithProvider function must return (T) or (T, error), found %d results", t.NumOut()))
+	}
+	if t.NumOut() == 2 && !t.Out(1).Implements(reflect.TypeOf((*error)(nil)).Elem()) {
+		panic("WithProvider function's second result must be an error")
+	}
+	if t.Out(0).Kind() != reflect.Ptr {
+		panic(fmt.Sprintf("WithProvider function must return a pointer (or a pointer and an error), found %s", t.Out(0)))
+	}
+
+	placeholder := reflect.New(t.Out(0).Elem())
+	p := &providerSpec{name: name, fn: v, fnType: t, placeholder: placeholder}
+
+	i.log("Adding %T from provider", placeholder.Interface())
+	// Complete: true tells facebookgo/inject not to populate the placeholder's own inject-tagged
+	// fields against the graph's current (pre-construction) state - resolveProvider fills it in
+	// wholesale once the provider function actually runs.
+	o := &inject.Object{Name: name, Value: placeholder.Interface(), Complete: true}
+	if err := i.g.Provide(o); err != nil {
+		panic(fmt.Sprintf("Error providing object %T from provider:%s", o.Value, err.Error()))
+	}
+	i.tmpObjects = append(i.tmpObjects, o)
+	if i.providerSpecs == nil {
+		i.providerSpecs = map[*inject.Object]*providerSpec{}
+	}
+	i.providerSpecs[o] = p
+	return i
+}
+
+// resolveProvider calls obj's provider, if obj is provider-backed, and copies the result into the
+// placeholder that was already provided into the graph at WithProvider time. It's a no-op for
+// objects that aren't provider-backed (and for ones already resolved). It's called right before
+// an object would be initialized; whether the provider's dependencies have had their own
+// Init/InitCtx called by then depends on the init order (see WithProvider).
+func (i *InjectWrapper) resolveProvider(obj *inject.Object) error {
+	p, ok := i.providerSpecs[obj]
+	if !ok || p.resolved {
+		return nil
+	}
+
+	args, ok := i.resolveProviderArgs(p)
+	if !ok {
+		return fmt.Errorf("could not resolve dependencies for provider %s", p.fnType)
+	}
+
+	i.log("Resolving %T from provider", p.placeholder.Interface())
+	out := p.fn.Call(args)
+	if len(out) == 2 {
+		if errVal, _ := out[1].Interface().(error); errVal != nil {
+			return fmt.Errorf("error from provider %s: %w", p.fnType, errVal)
+		}
+	}
+	if !out[0].IsNil() {
+		p.placeholder.Elem().Set(out[0].Elem())
+	}
+	p.resolved = true
+	return nil
+}
+
+func (i *InjectWrapper) resolveProviderArgs(p *providerSpec) ([]reflect.Value, bool) {
+	args := make([]reflect.Value, p.fnType.NumIn())
+	for idx := 0; idx < p.fnType.NumIn(); idx++ {
+		paramType := p.fnType.In(idx)
+
+		if paramType.Kind() == reflect.Struct && hasNameTags(paramType) {
+			v, ok := i.buildNamedParams(paramType)
+			if !ok {
+				return nil, false
+			}
+			args[idx] = v
+			continue
+		}
+
+		val, ok := i.findByType(paramType)
+		if !ok {
+			return nil, false
+		}
+		args[idx] = reflect.ValueOf(val)
+	}
+	return args, true
+}
+
+// providerArgDependencies resolves p's function parameters against all the same way
+// resolveProviderArgs does, but returns the matching *inject.Object themselves rather than their
+// values, so dependenciesOf can add them as dependency-graph edges.
+func providerArgDependencies(p *providerSpec, all []*inject.Object) []*inject.Object {
+	var deps []*inject.Object
+	for idx := 0; idx < p.fnType.NumIn(); idx++ {
+		paramType := p.fnType.In(idx)
+
+		if paramType.Kind() == reflect.Struct && hasNameTags(paramType) {
+			for f := 0; f < paramType.NumField(); f++ {
+				name, ok := paramType.Field(f).Tag.Lookup("name")
+				if !ok {
+					continue
+				}
+				for _, cand := range all {
+					if cand.Name == name {
+						deps = append(deps, cand)
+						break
+					}
+				}
+			}
+			continue
+		}
+
+		for _, cand := range all {
+			if cand.Name == "" && reflect.TypeOf(cand.Value).AssignableTo(paramType) {
+				deps = append(deps, cand)
+				break
+			}
+		}
+	}
+	return deps
+}
+
+func hasNameTags(t reflect.Type) bool {
+	for f := 0; f < t.NumField(); f++ {
+		if _, ok := t.Field(f).Tag.Lookup("name"); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (i *InjectWrapper) buildNamedParams(t reflect.Type) (reflect.Value, bool) {
+	out := reflect.New(t).Elem()
+	for f := 0; f < t.NumField(); f++ {
+		field := t.Field(f)
+		name, ok := field.Tag.Lookup("name")
+		if !ok {
+			continue
+		}
+		val, found := i.findByName(name)
+		if !found {
+			return reflect.Value{}, false
+		}
+		out.Field(f).Set(reflect.ValueOf(val))
+	}
+	return out, true
+}
+
+func (i *InjectWrapper) findByType(t reflect.Type) (interface{}, bool) {
+	for _, o := range i.allObjectsIncludingPending() {
+		if o.Name == "" && reflect.TypeOf(o.Value).AssignableTo(t) {
+			return o.Value, true
+		}
+	}
+	return nil, false
+}
+
+func (i *InjectWrapper) findByName(name string) (interface{}, bool) {
+	for _, o := range i.allObjectsIncludingPending() {
+		if o.Name == name {
+			return o.Value, true
+		}
+	}
+	return nil, false
+}
+
+// allObjectsIncludingPending is allRegisteredObjects plus anything still sitting in tmpObjects
+// (i.e. added since the last InitSync()/InitAsync()), followed by the objects inherited from a
+// parent via Scope(), so a scoped provider's parameters are matched against the child's own
+// objects first and the parent's after them.
+func (i *InjectWrapper) allObjectsIncludingPending() []*inject.Object {
+	all := i.allRegisteredObjects()
+	all = append(all, i.tmpObjects...)
+	return append(all, i.inherited...)
+}
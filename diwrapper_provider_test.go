@@ -0,0 +1,96 @@
+package diwrapper
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type providerConfig struct {
+	DSN string
+}
+
+type providerDriver struct {
+	DSN string
+}
+
+type providerRepo struct {
+	Driver *providerDriver `inject:""`
+}
+
+func TestWithProvider(t *testing.T) {
+	cfg := &providerConfig{DSN: "mysql://localhost"}
+	repo := new(providerRepo)
+
+	New().
+		WithObject(cfg).
+		WithProvider(func(c *providerConfig) *providerDriver {
+			return &providerDriver{DSN: c.DSN}
+		}).
+		WithObject(repo).
+		InitializeGraph()
+
+	assert.NotNil(t, repo.Driver)
+	assert.Equal(t, "mysql://localhost", repo.Driver.DSN)
+}
+
+type providerDBDriver struct {
+	ready bool
+}
+
+func (d *providerDBDriver) Init() error {
+	d.ready = true
+	return nil
+}
+
+type providerDBRepo struct {
+	Ready bool
+}
+
+func TestWithProviderSeesInitializedDependency(t *testing.T) {
+	driver := &providerDBDriver{}
+
+	di := New().
+		WithObject(driver).
+		WithProvider(func(d *providerDBDriver) *providerDBRepo {
+			return &providerDBRepo{Ready: d.ready}
+		}).
+		InitializeGraph()
+
+	repo := di.MustGetObject(&providerDBRepo{}).(*providerDBRepo)
+	assert.True(t, repo.Ready, "provider should run after its dependency's Init()")
+}
+
+func TestWithProviderErr(t *testing.T) {
+	defer func() {
+		r := recover()
+		assert.NotNil(t, r, "expected a panic when the provider returns an error")
+	}()
+
+	New().
+		WithProvider(func() (*providerDriver, error) {
+			return nil, fmt.Errorf("connection refused")
+		}).
+		InitializeGraph()
+
+	t.FailNow()
+}
+
+type namedProviderParams struct {
+	Primary *providerDriver `name:"primary"`
+}
+
+func TestWithProviderNamedParams(t *testing.T) {
+	primary := &providerDriver{DSN: "primary-dsn"}
+
+	di := New().
+		WithNamedObject("primary", primary).
+		WithProvider(func(p namedProviderParams) *providerRepo {
+			return &providerRepo{Driver: p.Primary}
+		}).
+		InitializeGraph()
+
+	repo := di.MustGetObject(&providerRepo{}).(*providerRepo)
+	assert.Equal(t, "primary-dsn", repo.Driver.DSN)
+}
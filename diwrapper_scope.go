@@ -0,0 +1,36 @@
+package diwrapper
+
+import (
+	"fmt"
+
+	"github.com/facebookgo/inject"
+)
+
+// Scope returns a child InjectWrapper that inherits i's already-populated objects without
+// re-initializing them, so request-scoped or job-scoped objects can be layered on top through
+// the usual With* fluent API without rebuilding a whole graph from scratch. Resolution for the
+// child's own objects first searches the child, then falls back to i; Stop() on the child only
+// cleans the child's own objects, leaving i's singletons alive, while Stop() on i cascades into
+// every child still registered.
+//
+// Scope must be called after i has been initialized (e.g. after InitializeGraph()), since it's
+// i's current objects that get inherited.
+func (i *InjectWrapper) Scope() *InjectWrapper {
+	child := New()
+	child.parent = i
+
+	for _, o := range i.allRegisteredObjects() {
+		inherited := &inject.Object{Name: o.Name, Value: o.Value}
+		if err := child.g.Provide(inherited); err != nil {
+			panic(fmt.Sprintf("Error inheriting object %T into scope:%s", o.Value, err.Error()))
+		}
+		// Tracked separately from child.objects: inherited objects must satisfy
+		// checkNoImplicitObjects, but must never be initialized or cleaned again by the child.
+		child.inherited = append(child.inherited, inherited)
+	}
+
+	i.childrenMu.Lock()
+	i.children = append(i.children, child)
+	i.childrenMu.Unlock()
+	return child
+}
@@ -0,0 +1,83 @@
+package diwrapper
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type scopeSingleton struct {
+	initialized bool
+	stopped     bool
+}
+
+func (s *scopeSingleton) Init() error {
+	s.initialized = true
+	return nil
+}
+
+func (s *scopeSingleton) Clean() error {
+	s.stopped = true
+	return nil
+}
+
+type scopeRequest struct {
+	Singleton *scopeSingleton `inject:""`
+	stopped   bool
+}
+
+func (s *scopeRequest) Clean() error {
+	s.stopped = true
+	return nil
+}
+
+func TestScopeInheritsParentObjects(t *testing.T) {
+	singleton := &scopeSingleton{}
+
+	root := New().WithObject(singleton).InitializeGraph()
+
+	req := &scopeRequest{}
+	root.Scope().WithObject(req).InitializeGraph()
+
+	assert.NotNil(t, req.Singleton)
+	assert.Same(t, singleton, req.Singleton)
+}
+
+func TestScopeStopOnlyCleansItsOwnObjects(t *testing.T) {
+	singleton := &scopeSingleton{}
+	root := New().WithObject(singleton).InitializeGraph()
+
+	req := &scopeRequest{}
+	child := root.Scope().WithObject(req).InitializeGraph()
+
+	child.Stop()
+
+	assert.True(t, req.stopped)
+	assert.False(t, singleton.stopped, "Stop() on a child must not clean up the parent's singletons")
+}
+
+func TestScopeStopPrunesChildFromParent(t *testing.T) {
+	singleton := &scopeSingleton{}
+	root := New().WithObject(singleton).InitializeGraph()
+
+	for n := 0; n < 1000; n++ {
+		req := &scopeRequest{}
+		child := root.Scope().WithObject(req).InitializeGraph()
+		child.Stop()
+	}
+
+	assert.Empty(t, root.children, "a stopped child must not be kept alive by its parent")
+}
+
+func TestRootStopCascadesToChildren(t *testing.T) {
+	singleton := &scopeSingleton{}
+	root := New().WithObject(singleton).InitializeGraph()
+
+	req := &scopeRequest{}
+	root.Scope().WithObject(req).InitializeGraph()
+
+	root.Stop()
+
+	assert.True(t, singleton.stopped)
+	assert.True(t, req.stopped, "Stop() on the root must cascade into live children")
+}